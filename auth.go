@@ -0,0 +1,23 @@
+package main
+
+import "github.com/gin-gonic/gin"
+
+// requireToken gates a route behind WALLPAPER_TOKEN, checked against a
+// `Bearer <token>` Authorization header. An empty token disables the
+// check entirely, which is the default for local/dev use.
+func requireToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		if c.GetHeader("Authorization") != "Bearer "+token {
+			c.JSON(401, gin.H{"success": false, "message": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// Config holds everything that used to be hardcoded constants, loaded once
+// from the environment at startup.
+type Config struct {
+	Host            string
+	Port            string
+	UnixSocket      string
+	ImageRoot       string
+	CORSOrigins     []string
+	Token           string
+	Categories      []string
+	ImageExtensions []string
+}
+
+// loadConfig reads WALLPAPER_* environment variables, falling back to the
+// project's long-standing defaults when they're unset.
+func loadConfig() Config {
+	cfg := Config{
+		Host:            getEnv("WALLPAPER_HOST", "0.0.0.0"),
+		Port:            getEnv("WALLPAPER_PORT", "8664"),
+		UnixSocket:      os.Getenv("WALLPAPER_UNIXSOCKET"),
+		ImageRoot:       getEnv("WALLPAPER_IMAGE_ROOT", "images"),
+		Token:           os.Getenv("WALLPAPER_TOKEN"),
+		Categories:      []string{"nature", "culture", "digital"},
+		ImageExtensions: []string{".jpg", ".jpeg", ".png", ".webp", ".bmp"},
+	}
+
+	if raw := os.Getenv("WALLPAPER_CORS_ORIGINS"); raw != "" {
+		for _, origin := range strings.Split(raw, ",") {
+			origin = strings.TrimSpace(origin)
+			if origin != "" {
+				cfg.CORSOrigins = append(cfg.CORSOrigins, origin)
+			}
+		}
+	}
+
+	return cfg
+}
+
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
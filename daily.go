@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RotationResponse wraps a single deterministically-selected wallpaper
+// along with the window during which it remains valid.
+type RotationResponse struct {
+	Success    bool      `json:"success"`
+	Data       Wallpaper `json:"data"`
+	ValidUntil time.Time `json:"valid_until"`
+	Message    string    `json:"message,omitempty"`
+}
+
+// allCategoriesKey stands in for the per-category key on the /wallpapers/daily
+// and /wallpapers/weekly routes, which pool every category instead of picking
+// one, so they still get their own independent rotation stream.
+const allCategoriesKey = "all"
+
+func getDailyWallpaper(c *gin.Context) {
+	category := c.Param("category")
+	if !isValidCategory(category) {
+		c.JSON(400, RotationResponse{Success: false, Message: "Invalid category. Use: nature, culture, or digital"})
+		return
+	}
+	serveRotation(c, []string{category}, dailyKey(category, time.Now().UTC()), endOfUTCDay(time.Now().UTC()))
+}
+
+func getWeeklyWallpaper(c *gin.Context) {
+	category := c.Param("category")
+	if !isValidCategory(category) {
+		c.JSON(400, RotationResponse{Success: false, Message: "Invalid category. Use: nature, culture, or digital"})
+		return
+	}
+	serveRotation(c, []string{category}, weeklyKey(category, time.Now().UTC()), endOfISOWeek(time.Now().UTC()))
+}
+
+func getDailyWallpaperAllCategories(c *gin.Context) {
+	serveRotation(c, categories, dailyKey(allCategoriesKey, time.Now().UTC()), endOfUTCDay(time.Now().UTC()))
+}
+
+func getWeeklyWallpaperAllCategories(c *gin.Context) {
+	serveRotation(c, categories, weeklyKey(allCategoriesKey, time.Now().UTC()), endOfISOWeek(time.Now().UTC()))
+}
+
+// serveRotation picks a deterministic wallpaper from the union of cats for
+// the given rotation key, and sets caching headers so CDNs and clients can
+// hold on to the response until validUntil.
+func serveRotation(c *gin.Context, cats []string, key string, validUntil time.Time) {
+	var records []WallpaperRecord
+	for _, cat := range cats {
+		catRecords, err := store.ByCategory(cat)
+		if err != nil {
+			c.JSON(500, RotationResponse{Success: false, Message: "Error loading wallpapers"})
+			return
+		}
+		records = append(records, catRecords...)
+	}
+
+	if len(records) == 0 {
+		c.JSON(404, RotationResponse{Success: false, Message: "No wallpapers found"})
+		return
+	}
+
+	// Sort by hash so the candidate list has a stable order regardless of
+	// scan or iteration order, which the seeded pick depends on.
+	sort.Slice(records, func(i, j int) bool { return records[i].Hash < records[j].Hash })
+
+	seed := int64(fnvHash(key))
+	r := rand.New(rand.NewSource(seed))
+	chosen := records[r.Intn(len(records))]
+
+	maxAge := int(time.Until(validUntil).Seconds())
+	if maxAge < 0 {
+		maxAge = 0
+	}
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", maxAge))
+
+	recordCategoryHit(chosen.Category)
+
+	c.JSON(200, RotationResponse{
+		Success:    true,
+		Data:       wallpaperFromRecord(c, chosen),
+		ValidUntil: validUntil,
+	})
+}
+
+func dailyKey(category string, t time.Time) string {
+	return fmt.Sprintf("%s|%s", category, t.Format("2006-01-02"))
+}
+
+func weeklyKey(category string, t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%s|%d-W%02d", category, year, week)
+}
+
+func endOfUTCDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
+
+func endOfISOWeek(t time.Time) time.Time {
+	// ISO weeks start on Monday; Go's Weekday has Sunday == 0.
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	y, m, d := t.Date()
+	startOfWeek := time.Date(y, m, d-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+	return startOfWeek.AddDate(0, 0, 7)
+}
+
+func fnvHash(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDailyKey(t *testing.T) {
+	got := dailyKey("nature", time.Date(2026, 7, 26, 15, 4, 5, 0, time.UTC))
+	want := "nature|2026-07-26"
+	if got != want {
+		t.Errorf("dailyKey() = %q, want %q", got, want)
+	}
+}
+
+func TestDailyKeyVariesByCategory(t *testing.T) {
+	// Each category must get its own rotation stream for the same day,
+	// not a shared one driven by the date alone.
+	day := time.Date(2026, 7, 26, 0, 0, 0, 0, time.UTC)
+	if dailyKey("nature", day) == dailyKey("culture", day) {
+		t.Error("dailyKey for different categories on the same day must differ")
+	}
+}
+
+func TestWeeklyKey(t *testing.T) {
+	// 2026-07-26 is a Sunday, still ISO week 30.
+	got := weeklyKey("nature", time.Date(2026, 7, 26, 0, 0, 0, 0, time.UTC))
+	want := "nature|2026-W30"
+	if got != want {
+		t.Errorf("weeklyKey() = %q, want %q", got, want)
+	}
+}
+
+func TestWeeklyKeyVariesByCategory(t *testing.T) {
+	week := time.Date(2026, 7, 26, 0, 0, 0, 0, time.UTC)
+	if weeklyKey("nature", week) == weeklyKey("culture", week) {
+		t.Error("weeklyKey for different categories in the same week must differ")
+	}
+}
+
+func TestEndOfUTCDay(t *testing.T) {
+	got := endOfUTCDay(time.Date(2026, 7, 26, 23, 59, 59, 0, time.UTC))
+	want := time.Date(2026, 7, 27, 0, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("endOfUTCDay() = %v, want %v", got, want)
+	}
+}
+
+func TestEndOfISOWeek(t *testing.T) {
+	// Wednesday 2026-07-22 is in ISO week 30, which runs Mon 2026-07-20
+	// through the start of Mon 2026-07-27.
+	got := endOfISOWeek(time.Date(2026, 7, 22, 12, 0, 0, 0, time.UTC))
+	want := time.Date(2026, 7, 27, 0, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("endOfISOWeek() = %v, want %v", got, want)
+	}
+
+	// A Monday should also resolve to the following Monday, not itself.
+	monday := endOfISOWeek(time.Date(2026, 7, 20, 0, 0, 0, 0, time.UTC))
+	if !monday.Equal(want) {
+		t.Errorf("endOfISOWeek(Monday) = %v, want %v", monday, want)
+	}
+}
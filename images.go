@@ -0,0 +1,361 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/image/draw"
+)
+
+// Fit controls how a source image is mapped onto the requested w x h box.
+type Fit int
+
+const (
+	FitCover Fit = iota
+	FitContain
+	FitCrop
+)
+
+func parseFit(raw string) Fit {
+	switch raw {
+	case "contain":
+		return FitContain
+	case "crop":
+		return FitCrop
+	default:
+		return FitCover
+	}
+}
+
+const (
+	defaultQuality = 85
+	cacheRoot      = "cache"
+	cacheMaxBytes  = 512 * 1024 * 1024 // 512MB LRU-bounded cache
+)
+
+var cachePruneMu sync.Mutex
+
+// serveImage handles GET /images/:category/:file, transcoding and resizing
+// the source image on first request and serving the cached variant on
+// every subsequent one.
+func serveImage(c *gin.Context) {
+	category := c.Param("category")
+	file := c.Param("file")
+
+	if !isValidCategory(category) || !isImageFile(file) {
+		c.JSON(404, gin.H{"success": false, "message": "Image not found"})
+		return
+	}
+
+	sourcePath := filepath.Join(imageRoot, category, file)
+	sourceInfo, err := os.Stat(sourcePath)
+	if err != nil {
+		c.JSON(404, gin.H{"success": false, "message": "Image not found"})
+		return
+	}
+
+	width, _ := strconv.Atoi(c.Query("w"))
+	height, _ := strconv.Atoi(c.Query("h"))
+	quality, err := strconv.Atoi(c.Query("q"))
+	if err != nil || quality < 1 || quality > 100 {
+		quality = defaultQuality
+	}
+	fit := parseFit(c.Query("fit"))
+	format := resolveFormat(c.Query("format"), c.GetHeader("Accept"), file)
+
+	sourceHash, err := hashFile(sourcePath)
+	if err != nil {
+		c.JSON(500, gin.H{"success": false, "message": "Error reading image"})
+		return
+	}
+
+	etag := fmt.Sprintf(`"%s"`, fnvHex(fmt.Sprintf("%s|%d|%d|%d|%s|%s", sourceHash, width, height, quality, fit.String(), format)))
+	lastModified := sourceInfo.ModTime().UTC()
+
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+
+	if match := c.GetHeader("If-None-Match"); match != "" && match == etag {
+		c.Status(304)
+		return
+	}
+	if since := c.GetHeader("If-Modified-Since"); since != "" {
+		if t, err := time.Parse(http.TimeFormat, since); err == nil && !lastModified.After(t) {
+			c.Status(304)
+			return
+		}
+	}
+
+	// A bare request for the image (no resize, no transcode) should hand
+	// back the original bytes untouched rather than round-tripping them
+	// through decode/encode, which would silently degrade quality and
+	// force unsupported source formats (webp, bmp, ...) to jpeg.
+	servePath := sourcePath
+	if width != 0 || height != 0 || format != sourceFormat(file) {
+		variantPath := filepath.Join(cacheRoot, sourceHash, variantCacheName(width, height, quality, fit, format))
+		if _, err := os.Stat(variantPath); os.IsNotExist(err) {
+			recordCacheResult(false)
+			if err := generateVariant(sourcePath, variantPath, width, height, quality, fit, format); err != nil {
+				c.JSON(500, gin.H{"success": false, "message": fmt.Sprintf("Error transcoding image: %v", err)})
+				return
+			}
+			go pruneCache(cacheRoot, cacheMaxBytes)
+		} else {
+			recordCacheResult(true)
+		}
+		servePath = variantPath
+	}
+
+	out, err := os.Open(servePath)
+	if err != nil {
+		c.JSON(500, gin.H{"success": false, "message": "Error serving image"})
+		return
+	}
+	defer out.Close()
+
+	c.Header("Content-Type", contentTypeForFormat(format))
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.Status(200)
+	io.Copy(c.Writer, out)
+}
+
+// variantCacheName builds the cache filename for a resized/transcoded
+// variant. fit must be included alongside w/h/q/format: two requests that
+// differ only in fit produce different pixels and must not collide on the
+// same cache file (it's already part of the ETag, so the value is on hand).
+func variantCacheName(width, height, quality int, fit Fit, format string) string {
+	return fmt.Sprintf("%dx%d_%d_%s.%s", width, height, quality, fit.String(), format)
+}
+
+func (f Fit) String() string {
+	switch f {
+	case FitContain:
+		return "contain"
+	case FitCrop:
+		return "crop"
+	default:
+		return "cover"
+	}
+}
+
+// resolveFormat honors an explicit ?format=, falls back to content
+// negotiation via Accept for format=auto (or no format at all), and
+// otherwise preserves the source file's own extension.
+func resolveFormat(requested, accept, sourceFile string) string {
+	switch requested {
+	case "jpeg", "png":
+		return requested
+	case "webp":
+		// No pure-Go webp encoder ships in this build; degrade like avif
+		// rather than returning a mislabeled jpeg body.
+		return "jpeg"
+	case "auto", "":
+		if ext := sourceFormat(sourceFile); ext == "jpeg" || ext == "png" {
+			return ext
+		}
+		return "jpeg"
+	default:
+		// Unsupported formats (e.g. avif, for which this build has no
+		// encoder available) degrade to jpeg rather than failing outright.
+		return "jpeg"
+	}
+}
+
+func contentTypeForFormat(format string) string {
+	switch format {
+	case "png":
+		return "image/png"
+	case "webp":
+		return "image/webp"
+	case "bmp":
+		return "image/bmp"
+	default:
+		return "image/jpeg"
+	}
+}
+
+// sourceFormat returns the normalized format implied by file's extension,
+// independent of what encodeImage is able to produce. It's used to detect
+// when a request can be served as a passthrough of the original bytes.
+func sourceFormat(file string) string {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(file), "."))
+	if ext == "jpg" {
+		ext = "jpeg"
+	}
+	return ext
+}
+
+// generateVariant resizes/crops the source image per w, h and fit, encodes
+// it as format at the given quality, and writes it to variantPath.
+func generateVariant(sourcePath, variantPath string, width, height, quality int, fit Fit, format string) error {
+	src, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	img, _, err := image.Decode(src)
+	if err != nil {
+		return err
+	}
+
+	if width > 0 || height > 0 {
+		img = resizeImage(img, width, height, fit)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(variantPath), 0755); err != nil {
+		return err
+	}
+
+	tmpPath := variantPath + ".tmp"
+	dst, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	if err := encodeImage(dst, img, format, quality); err != nil {
+		dst.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return os.Rename(tmpPath, variantPath)
+}
+
+func resizeImage(img image.Image, width, height int, fit Fit) image.Image {
+	srcBounds := img.Bounds()
+	srcW, srcH := srcBounds.Dx(), srcBounds.Dy()
+
+	if width == 0 {
+		width = int(float64(srcW) * float64(height) / float64(srcH))
+	}
+	if height == 0 {
+		height = int(float64(srcH) * float64(width) / float64(srcW))
+	}
+
+	switch fit {
+	case FitCrop:
+		cropW, cropH := width, height
+		if cropW > srcW {
+			cropW = srcW
+		}
+		if cropH > srcH {
+			cropH = srcH
+		}
+		cropped := image.NewRGBA(image.Rect(0, 0, cropW, cropH))
+		draw.Draw(cropped, cropped.Bounds(), img, srcBounds.Min, draw.Src)
+		return cropped
+	case FitContain:
+		scale := minFloat(float64(width)/float64(srcW), float64(height)/float64(srcH))
+		dstW := int(float64(srcW) * scale)
+		dstH := int(float64(srcH) * scale)
+		dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, srcBounds, draw.Over, nil)
+		return dst
+	default: // FitCover
+		scale := maxFloat(float64(width)/float64(srcW), float64(height)/float64(srcH))
+		scaledW := int(float64(srcW) * scale)
+		scaledH := int(float64(srcH) * scale)
+		scaled := image.NewRGBA(image.Rect(0, 0, scaledW, scaledH))
+		draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, srcBounds, draw.Over, nil)
+
+		offsetX := (scaledW - width) / 2
+		offsetY := (scaledH - height) / 2
+		cropped := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(cropped, cropped.Bounds(), scaled, image.Pt(offsetX, offsetY), draw.Src)
+		return cropped
+	}
+}
+
+func encodeImage(w io.Writer, img image.Image, format string, quality int) error {
+	switch format {
+	case "png":
+		return png.Encode(w, img)
+	case "webp", "jpeg":
+		// No pure-Go webp encoder ships in this build; webp requests are
+		// encoded as jpeg until a cgo-backed encoder is wired in.
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	default:
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	}
+}
+
+// pruneCache deletes the least-recently-modified cached variants until the
+// cache directory's total size is back under maxBytes.
+func pruneCache(root string, maxBytes int64) {
+	cachePruneMu.Lock()
+	defer cachePruneMu.Unlock()
+
+	type entry struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+
+	var entries []entry
+	var total int64
+
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		entries = append(entries, entry{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+		return nil
+	})
+
+	if total <= maxBytes {
+		return
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].modTime.Before(entries[j].modTime) })
+
+	for _, e := range entries {
+		if total <= maxBytes {
+			break
+		}
+		if err := os.Remove(e.path); err == nil {
+			total -= e.size
+		}
+	}
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func fnvHex(s string) string {
+	return fmt.Sprintf("%08x", fnvHash(s))
+}
+
+// thumbnailURL builds a small preview URL for imageURL, e.g. for mobile
+// clients that shouldn't download full-resolution originals up front.
+func thumbnailURL(imageURL string) string {
+	return fmt.Sprintf("%s?w=400", imageURL)
+}
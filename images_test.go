@@ -0,0 +1,85 @@
+package main
+
+import (
+	"image"
+	"testing"
+)
+
+func TestResolveFormatExplicitWebpDowngradesToJPEG(t *testing.T) {
+	// No pure-Go webp encoder ships in this build, so an explicit
+	// ?format=webp must resolve to jpeg rather than promising a format
+	// encodeImage can't actually produce.
+	if got := resolveFormat("webp", "", "photo.png"); got != "jpeg" {
+		t.Errorf("resolveFormat(webp) = %q, want jpeg", got)
+	}
+}
+
+func TestResolveFormatAutoIgnoresWebpAccept(t *testing.T) {
+	if got := resolveFormat("", "image/webp,image/*", "photo.jpg"); got != "jpeg" {
+		t.Errorf("resolveFormat(auto, Accept: webp) = %q, want jpeg", got)
+	}
+}
+
+func TestVariantCacheNameIncludesFit(t *testing.T) {
+	// Regression test: two requests with identical w/h/q/format but
+	// different fit must not collide on the same cache file.
+	cover := variantCacheName(200, 200, 85, FitCover, "jpeg")
+	crop := variantCacheName(200, 200, 85, FitCrop, "jpeg")
+
+	if cover == crop {
+		t.Errorf("variant cache names for different fits must differ, both got %q", cover)
+	}
+}
+
+func TestResizeImageCover(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	out := resizeImage(src, 100, 100, FitCover)
+
+	if b := out.Bounds(); b.Dx() != 100 || b.Dy() != 100 {
+		t.Errorf("FitCover bounds = %dx%d, want 100x100", b.Dx(), b.Dy())
+	}
+}
+
+func TestResizeImageContain(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	out := resizeImage(src, 100, 100, FitContain)
+
+	b := out.Bounds()
+	if b.Dx() != 100 || b.Dy() != 50 {
+		t.Errorf("FitContain bounds = %dx%d, want 100x50", b.Dx(), b.Dy())
+	}
+}
+
+func TestResizeImageCrop(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	out := resizeImage(src, 100, 100, FitCrop)
+
+	if b := out.Bounds(); b.Dx() != 100 || b.Dy() != 100 {
+		t.Errorf("FitCrop bounds = %dx%d, want 100x100", b.Dx(), b.Dy())
+	}
+}
+
+func TestResolveFormatAutoPreservesSourceExtension(t *testing.T) {
+	if got := resolveFormat("auto", "", "photo.png"); got != "png" {
+		t.Errorf("resolveFormat(auto) = %q, want png", got)
+	}
+}
+
+func TestSourceFormat(t *testing.T) {
+	cases := []struct {
+		file string
+		want string
+	}{
+		{"photo.jpg", "jpeg"},
+		{"photo.jpeg", "jpeg"},
+		{"photo.PNG", "png"},
+		{"photo.webp", "webp"},
+		{"photo.bmp", "bmp"},
+	}
+
+	for _, tc := range cases {
+		if got := sourceFormat(tc.file); got != tc.want {
+			t.Errorf("sourceFormat(%q) = %q, want %q", tc.file, got, tc.want)
+		}
+	}
+}
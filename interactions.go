@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// clientKey identifies the caller for idempotency purposes: an explicit
+// client token if one was supplied, falling back to their IP address.
+func clientKey(c *gin.Context) string {
+	if token := c.GetHeader("X-Client-Token"); token != "" {
+		return token
+	}
+	return c.ClientIP()
+}
+
+func postWallpaperView(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"success": false, "message": "Invalid wallpaper id"})
+		return
+	}
+
+	record, ok, err := store.ByID(id)
+	if err != nil {
+		c.JSON(500, gin.H{"success": false, "message": "Error looking up wallpaper"})
+		return
+	}
+	if !ok {
+		c.JSON(404, gin.H{"success": false, "message": "Wallpaper not found"})
+		return
+	}
+
+	views, err := store.RecordView(record.Hash, clientKey(c))
+	if err != nil {
+		c.JSON(500, gin.H{"success": false, "message": "Error recording view"})
+		return
+	}
+
+	c.JSON(200, gin.H{"success": true, "views": views})
+}
+
+func postWallpaperFavorite(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"success": false, "message": "Invalid wallpaper id"})
+		return
+	}
+
+	record, ok, err := store.ByID(id)
+	if err != nil {
+		c.JSON(500, gin.H{"success": false, "message": "Error looking up wallpaper"})
+		return
+	}
+	if !ok {
+		c.JSON(404, gin.H{"success": false, "message": "Wallpaper not found"})
+		return
+	}
+
+	favorites, err := store.RecordFavorite(record.Hash, clientKey(c))
+	if err != nil {
+		c.JSON(500, gin.H{"success": false, "message": "Error recording favorite"})
+		return
+	}
+
+	c.JSON(200, gin.H{"success": true, "favorites": favorites})
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// devMode mirrors pixivfe's PIXIVFE_DEV toggle: when set, logs are
+// pretty-printed for a human reading a terminal instead of emitted as
+// single-line JSON for a log collector.
+var devMode = os.Getenv("WALLPAPER_DEV") == "true"
+
+func init() {
+	if devMode {
+		log.Logger = zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339}).With().Timestamp().Logger()
+	} else {
+		log.Logger = zerolog.New(os.Stdout).With().Timestamp().Logger()
+	}
+}
+
+// structuredLogger replaces gin's default logger with one JSON (or, in dev
+// mode, pretty-printed) line per request, carrying enough fields to debug
+// latency and traffic patterns without needing access logs.
+func structuredLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		path := c.Request.URL.Path
+		if raw := c.Request.URL.RawQuery; raw != "" {
+			path = path + "?" + raw
+		}
+
+		c.Next()
+
+		latency := time.Since(start)
+		route := c.FullPath()
+		if route == "" {
+			route = path
+		}
+
+		requestDuration.WithLabelValues(route, c.Request.Method, statusBucket(c.Writer.Status())).Observe(latency.Seconds())
+
+		log.Info().
+			Str("method", c.Request.Method).
+			Str("path", path).
+			Int("status", c.Writer.Status()).
+			Int("bytes", c.Writer.Size()).
+			Str("remote_ip", c.ClientIP()).
+			Str("x_forwarded_for", c.Request.Header.Get("X-Forwarded-For")).
+			Str("user_agent", c.Request.UserAgent()).
+			Dur("latency", latency).
+			Msg("request")
+	}
+}
+
+func statusBucket(status int) string {
+	switch {
+	case status >= 500:
+		return "5xx"
+	case status >= 400:
+		return "4xx"
+	case status >= 300:
+		return "3xx"
+	default:
+		return "2xx"
+	}
+}
@@ -2,24 +2,42 @@ package main
 
 import (
 	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
 	"io/ioutil"
 	"log"
 	"math/rand"
+	"net"
+	"net/http"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/webp"
 )
 
 type Wallpaper struct {
-	ID         int      `json:"id"`
-	Title      string   `json:"title"`
-	ImageURL   string   `json:"imageUrl"`
-	Category   string   `json:"category"`
-	Tags       []string `json:"tags"`
-	Resolution string   `json:"resolution"`
+	ID           int       `json:"id"`
+	Title        string    `json:"title"`
+	ImageURL     string    `json:"imageUrl"`
+	ThumbnailURL string    `json:"thumbnailUrl"`
+	Category     string    `json:"category"`
+	Tags         []string  `json:"tags"`
+	Resolution   string    `json:"resolution"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	UploadedAt   time.Time `json:"uploaded_at"`
+	Views        int       `json:"views"`
+	Favorites    int       `json:"favorites"`
+	Credit       string    `json:"credit,omitempty"`
+	License      string    `json:"license,omitempty"`
+	NSFW         bool      `json:"nsfw"`
 }
 
 type APIResponse struct {
@@ -28,38 +46,81 @@ type APIResponse struct {
 	Message string      `json:"message,omitempty"`
 }
 
+// imageExtensions and categories are populated from Config at startup;
+// they default to the values below if left unset in code that runs before
+// main (namely tests).
 var (
 	imageExtensions = []string{".jpg", ".jpeg", ".png", ".webp", ".bmp"}
 	categories      = []string{"nature", "culture", "digital"}
-	resolutions     = []string{"1080x1920", "1440x2560", "2160x3840", "1080x2340", "1170x2532"}
 )
 
+// store is the persistent wallpaper catalog, opened once in main and
+// shared by every request handler.
+var store *Store
+
+// imageRoot is the directory image files and per-category manifests are
+// read from, set from Config at startup.
+var imageRoot = "images"
+
+const scanInterval = 10 * time.Minute
+
 func main() {
 	// Initialize random seed
 	rand.Seed(time.Now().UnixNano())
 
-	// Create Gin router
-	r := gin.Default()
+	cfg := loadConfig()
+	categories = cfg.Categories
+	imageExtensions = cfg.ImageExtensions
+	imageRoot = cfg.ImageRoot
 
-	// Enable CORS for Android app
-	config := cors.DefaultConfig()
-	config.AllowAllOrigins = true
-	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
-	r.Use(cors.New(config))
+	var err error
+	store, err = NewStore("wallpapers.db")
+	if err != nil {
+		log.Fatalf("Cannot open wallpaper store: %v", err)
+	}
+	defer store.Close()
+
+	scanner := NewScanner(store, cfg.ImageRoot, scanInterval)
+	go scanner.Run(nil)
+
+	// Create Gin router with structured request logging in place of the
+	// default logger; Recovery is kept so panics still return a 500.
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.Use(structuredLogger())
+
+	// Enable CORS, restricted to WALLPAPER_CORS_ORIGINS when set
+	corsConfig := cors.DefaultConfig()
+	if len(cfg.CORSOrigins) > 0 {
+		corsConfig.AllowOrigins = cfg.CORSOrigins
+	} else {
+		corsConfig.AllowAllOrigins = true
+	}
+	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
+	r.Use(cors.New(corsConfig))
 
 	// API routes
 	api := r.Group("/api/v1")
 	{
 		api.GET("/wallpapers/:category", getWallpapersByCategory)
 		api.GET("/wallpapers/:category/random", getRandomWallpaper)
+		api.GET("/wallpapers/:category/daily", getDailyWallpaper)
+		api.GET("/wallpapers/:category/weekly", getWeeklyWallpaper)
+		api.GET("/wallpapers/daily", getDailyWallpaperAllCategories)
+		api.GET("/wallpapers/weekly", getWeeklyWallpaperAllCategories)
 		api.GET("/wallpapers", getAllWallpapers)
+		api.GET("/search", getSearchResults)
+		api.POST("/wallpapers/:id/view", postWallpaperView)
+		api.POST("/wallpapers/:id/favorite", postWallpaperFavorite)
 		api.GET("/categories", getCategories)
 		api.GET("/privacy-policy", getPrivacyPolicyJSON)
 	}
 
-	// Serve static images
-	r.Static("/images", "./images")
+	// Serve images through the resize/transcode pipeline instead of a
+	// plain static mount, so clients can request thumbnails and CDNs can
+	// cache on ETag/Last-Modified.
+	r.GET("/images/:category/:file", serveImage)
 
 	// Privacy policy HTML route
 	r.GET("/privacy-policy", getPrivacyPolicy) // Health check
@@ -67,21 +128,45 @@ func main() {
 		c.JSON(200, gin.H{"status": "ok", "message": "Wallpaper API is running"})
 	})
 
+	// Prometheus metrics, gated behind WALLPAPER_TOKEN when one is set
+	r.GET("/metrics", requireToken(cfg.Token), metricsHandler())
+
 	// Start server
-	fmt.Println("🚀 Wallpaper API Server starting on http://localhost:8664")
+	fmt.Println("🚀 Wallpaper API Server starting")
 	fmt.Println("📁 Place your images in:")
-	fmt.Println("   - images/nature/")
-	fmt.Println("   - images/culture/")
-	fmt.Println("   - images/digital/")
+	for _, category := range categories {
+		fmt.Printf("   - %s/%s/\n", cfg.ImageRoot, category)
+	}
 	fmt.Println("📡 API Endpoints:")
-	fmt.Println("   - GET /api/v1/wallpapers/nature")
-	fmt.Println("   - GET /api/v1/wallpapers/culture")
-	fmt.Println("   - GET /api/v1/wallpapers/digital")
+	for _, category := range categories {
+		fmt.Printf("   - GET /api/v1/wallpapers/%s\n", category)
+	}
 	fmt.Println("   - GET /api/v1/wallpapers/{category}/random")
 	fmt.Println("   - GET /privacy-policy (HTML)")
 	fmt.Println("   - GET /api/v1/privacy-policy (JSON)")
 
-	log.Fatal(r.Run(":8664"))
+	log.Fatal(listenAndServe(r, cfg))
+}
+
+// listenAndServe runs r on a unix socket when cfg.UnixSocket is set
+// (for sitting behind an nginx reverse proxy without a public TCP port),
+// otherwise on cfg.Host:cfg.Port.
+func listenAndServe(r *gin.Engine, cfg Config) error {
+	if cfg.UnixSocket != "" {
+		_ = os.Remove(cfg.UnixSocket)
+
+		listener, err := net.Listen("unix", cfg.UnixSocket)
+		if err != nil {
+			return fmt.Errorf("cannot listen on unix socket %s: %v", cfg.UnixSocket, err)
+		}
+
+		fmt.Printf("   - listening on unix socket %s\n", cfg.UnixSocket)
+		return http.Serve(listener, r)
+	}
+
+	addr := fmt.Sprintf("%s:%s", cfg.Host, cfg.Port)
+	fmt.Printf("   - listening on http://%s\n", addr)
+	return r.Run(addr)
 }
 
 func getWallpapersByCategory(c *gin.Context) {
@@ -96,19 +181,23 @@ func getWallpapersByCategory(c *gin.Context) {
 		return
 	}
 
-	wallpapers, err := loadWallpapersFromFolder(c, category)
+	params, err := parseSearchParams(c)
 	if err != nil {
-		c.JSON(500, APIResponse{
+		c.JSON(400, SearchResponse{Success: false, Message: err.Error()})
+		return
+	}
+
+	data, meta, err := searchWallpapers(c, params, category)
+	if err != nil {
+		c.JSON(500, SearchResponse{
 			Success: false,
 			Message: fmt.Sprintf("Error loading wallpapers: %v", err),
 		})
 		return
 	}
 
-	c.JSON(200, APIResponse{
-		Success: true,
-		Data:    wallpapers,
-	})
+	recordCategoryHit(category)
+	c.JSON(200, SearchResponse{Success: true, Data: data, Meta: meta})
 }
 
 func getRandomWallpaper(c *gin.Context) {
@@ -140,10 +229,13 @@ func getRandomWallpaper(c *gin.Context) {
 		return
 	}
 
-	// Get random wallpaper
-	randomIndex := rand.Intn(len(wallpapers))
+	// Get random wallpaper using a request-scoped RNG so concurrent
+	// requests don't contend on the shared global generator's lock.
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	randomIndex := r.Intn(len(wallpapers))
 	randomWallpaper := wallpapers[randomIndex]
 
+	recordCategoryHit(category)
 	c.JSON(200, APIResponse{
 		Success: true,
 		Data:    []Wallpaper{randomWallpaper},
@@ -175,51 +267,52 @@ func getCategories(c *gin.Context) {
 	})
 }
 
+// loadWallpapersFromFolder returns the catalogued wallpapers for category,
+// backed by the persistent Store rather than a fresh directory listing, so
+// IDs, titles, tags, views and favorites stay stable across requests.
 func loadWallpapersFromFolder(c *gin.Context, category string) ([]Wallpaper, error) {
-	folderPath := filepath.Join("images", category)
-
-	files, err := ioutil.ReadDir(folderPath)
+	records, err := store.ByCategory(category)
 	if err != nil {
-		return nil, fmt.Errorf("cannot read directory %s: %v", folderPath, err)
+		return nil, fmt.Errorf("cannot load %s wallpapers: %v", category, err)
 	}
 
-	var wallpapers []Wallpaper
-	id := 1
-
-	for _, file := range files {
-		if file.IsDir() {
-			continue
-		}
-
-		// Check if file is an image
-		if !isImageFile(file.Name()) {
-			continue
-		}
-
-		// Generate dynamic base URL from request
-		scheme := "http"
-		if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" || c.Request.Header.Get("X-Forwarded-Proto") == "https" {
-			scheme = "https"
-		}
-		baseURL := fmt.Sprintf("%s://%s", scheme, c.Request.Host)
-
-		// Generate wallpaper data
-		wallpaper := Wallpaper{
-			ID:         id,
-			Title:      generateRandomTitle(category),
-			ImageURL:   fmt.Sprintf("%s/images/%s/%s", baseURL, category, file.Name()),
-			Category:   strings.Title(category),
-			Tags:       generateRandomTags(category),
-			Resolution: getRandomResolution(),
-		}
-
-		wallpapers = append(wallpapers, wallpaper)
-		id++
+	wallpapers := make([]Wallpaper, 0, len(records))
+	for _, record := range records {
+		wallpapers = append(wallpapers, wallpaperFromRecord(c, record))
 	}
 
 	return wallpapers, nil
 }
 
+// wallpaperFromRecord converts a persisted WallpaperRecord into the public
+// Wallpaper shape, resolving its image URL against the current request.
+func wallpaperFromRecord(c *gin.Context, record WallpaperRecord) Wallpaper {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	baseURL := fmt.Sprintf("%s://%s", scheme, c.Request.Host)
+	imageURL := fmt.Sprintf("%s/images/%s/%s", baseURL, record.Category, record.Filename)
+
+	return Wallpaper{
+		ID:           record.ID,
+		Title:        record.Title,
+		ImageURL:     imageURL,
+		ThumbnailURL: thumbnailURL(imageURL),
+		Category:     strings.Title(record.Category),
+		Tags:         record.Tags,
+		Resolution:   fmt.Sprintf("%dx%d", record.Width, record.Height),
+		Width:        record.Width,
+		Height:       record.Height,
+		UploadedAt:   record.UploadedAt,
+		Views:        record.Views,
+		Favorites:    record.Favorites,
+		Credit:       record.Credit,
+		License:      record.License,
+		NSFW:         record.NSFW,
+	}
+}
+
 func isValidCategory(category string) bool {
 	for _, validCategory := range categories {
 		if category == validCategory {
@@ -296,8 +389,21 @@ func generateRandomTags(category string) []string {
 	return shuffled[:numTags]
 }
 
-func getRandomResolution() string {
-	return resolutions[rand.Intn(len(resolutions))]
+// decodeImageDimensions reads just enough of the image to determine its
+// real pixel dimensions, without loading the full file into memory.
+func decodeImageDimensions(path string) (int, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return cfg.Width, cfg.Height, nil
 }
 
 func getPrivacyPolicy(c *gin.Context) {
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestEntry lets a curator override the per-file metadata the scanner
+// would otherwise invent, keyed by filename in a category's manifest.yaml.
+type ManifestEntry struct {
+	Title   string   `yaml:"title"`
+	Tags    []string `yaml:"tags"`
+	Credit  string   `yaml:"credit"`
+	License string   `yaml:"license"`
+	NSFW    bool     `yaml:"nsfw"`
+}
+
+// Manifest maps filename to its curator-supplied overrides.
+type Manifest map[string]ManifestEntry
+
+// loadManifest reads images/<category>/manifest.yaml, if present. A
+// missing manifest is not an error: categories are free to omit one.
+func loadManifest(imageRoot, category string) (Manifest, error) {
+	path := filepath.Join(imageRoot, category, "manifest.yaml")
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Manifest{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read manifest %s: %v", path, err)
+	}
+
+	var manifest Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("cannot parse manifest %s: %v", path, err)
+	}
+
+	return manifest, nil
+}
+
+// applyManifestOverride copies curator-supplied fields onto record. It is
+// a no-op when override is nil, leaving the record's existing/generated
+// values untouched.
+func applyManifestOverride(record *WallpaperRecord, override *ManifestEntry) {
+	if override == nil {
+		return
+	}
+	if override.Title != "" {
+		record.Title = override.Title
+	}
+	if len(override.Tags) > 0 {
+		record.Tags = override.Tags
+	}
+	record.Credit = override.Credit
+	record.License = override.License
+	record.NSFW = override.NSFW
+}
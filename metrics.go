@@ -0,0 +1,79 @@
+package main
+
+import (
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "wallpaper_api_request_duration_seconds",
+		Help:    "HTTP request latency in seconds, by route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	categoryHits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "wallpaper_api_category_hits_total",
+		Help: "Number of requests served for each wallpaper category.",
+	}, []string{"category"})
+
+	imagesPerCategory = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "wallpaper_api_images_per_category",
+		Help: "Number of catalogued images currently in each category.",
+	}, []string{"category"})
+
+	imageCacheHitRatio = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "wallpaper_api_image_cache_hit_ratio",
+		Help: "Ratio of image-transcoding cache hits to total variant requests.",
+	})
+)
+
+// cacheHits and cacheMisses back imageCacheHitRatio; they're plain
+// counters rather than prometheus.Counters because the ratio gauge needs
+// to read both values together on every update.
+var cacheHits, cacheMisses int64
+
+func init() {
+	prometheus.MustRegister(requestDuration, categoryHits, imagesPerCategory, imageCacheHitRatio)
+}
+
+// recordCacheResult updates the hit/miss counters backing
+// imageCacheHitRatio. Call it once per /images request.
+func recordCacheResult(hit bool) {
+	if hit {
+		atomic.AddInt64(&cacheHits, 1)
+	} else {
+		atomic.AddInt64(&cacheMisses, 1)
+	}
+
+	hits := atomic.LoadInt64(&cacheHits)
+	misses := atomic.LoadInt64(&cacheMisses)
+	if total := hits + misses; total > 0 {
+		imageCacheHitRatio.Set(float64(hits) / float64(total))
+	}
+}
+
+// recordCategoryHit increments the per-category request counter. Safe to
+// call with an empty or invalid category, which is simply ignored.
+func recordCategoryHit(category string) {
+	if !isValidCategory(category) {
+		return
+	}
+	categoryHits.WithLabelValues(category).Inc()
+}
+
+// setImagesPerCategory updates the catalog-size gauge; called by the
+// Scanner after each pass.
+func setImagesPerCategory(category string, count int) {
+	imagesPerCategory.WithLabelValues(category).Set(float64(count))
+}
+
+func metricsHandler() gin.HandlerFunc {
+	h := promhttp.Handler()
+	return func(c *gin.Context) {
+		h.ServeHTTP(c.Writer, c.Request)
+	}
+}
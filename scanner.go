@@ -0,0 +1,149 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Scanner periodically walks the image root and reconciles it against the
+// Store, so the catalog stays in sync with files added or removed on disk.
+type Scanner struct {
+	store    *Store
+	root     string
+	interval time.Duration
+}
+
+// NewScanner builds a scanner over root, rescanning every interval.
+func NewScanner(store *Store, root string, interval time.Duration) *Scanner {
+	return &Scanner{store: store, root: root, interval: interval}
+}
+
+// Run performs an immediate scan and then rescans on a ticker until stop
+// is closed. It is meant to be launched with `go scanner.Run(stop)`.
+func (sc *Scanner) Run(stop <-chan struct{}) {
+	sc.scanOnce()
+
+	ticker := time.NewTicker(sc.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			sc.scanOnce()
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (sc *Scanner) scanOnce() {
+	seen := make(map[string]bool)
+
+	for _, category := range categories {
+		folderPath := filepath.Join(sc.root, category)
+
+		entries, err := os.ReadDir(folderPath)
+		if err != nil {
+			log.Printf("scanner: cannot read %s: %v", folderPath, err)
+			continue
+		}
+
+		manifest, err := loadManifest(sc.root, category)
+		if err != nil {
+			log.Printf("scanner: %v", err)
+			manifest = Manifest{}
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || !isImageFile(entry.Name()) {
+				continue
+			}
+
+			path := filepath.Join(folderPath, entry.Name())
+			hash, err := hashFile(path)
+			if err != nil {
+				log.Printf("scanner: cannot hash %s: %v", path, err)
+				continue
+			}
+
+			width, height, err := decodeImageDimensions(path)
+			if err != nil {
+				log.Printf("scanner: cannot decode %s: %v", path, err)
+			}
+
+			info, err := entry.Info()
+			uploadedAt := time.Now()
+			if err == nil {
+				uploadedAt = info.ModTime()
+			}
+
+			var override *ManifestEntry
+			if manifestEntry, ok := manifest[entry.Name()]; ok {
+				override = &manifestEntry
+			}
+
+			if _, err := sc.store.GetOrCreate(category, entry.Name(), hash, width, height, uploadedAt, override); err != nil {
+				log.Printf("scanner: cannot catalog %s: %v", path, err)
+				continue
+			}
+
+			seen[hash] = true
+		}
+	}
+
+	sc.reconcileRemovals(seen)
+	sc.reportCatalogSize()
+}
+
+// reportCatalogSize refreshes the images-per-category metrics gauge so it
+// reflects the catalog state after this scan pass.
+func (sc *Scanner) reportCatalogSize() {
+	for _, category := range categories {
+		records, err := sc.store.ByCategory(category)
+		if err != nil {
+			log.Printf("scanner: cannot count %s records: %v", category, err)
+			continue
+		}
+		setImagesPerCategory(category, len(records))
+	}
+}
+
+// reconcileRemovals marks any catalogued file that was not seen in this
+// pass as removed, without deleting its view/favorite history.
+func (sc *Scanner) reconcileRemovals(seen map[string]bool) {
+	for _, category := range categories {
+		records, err := sc.store.ByCategory(category)
+		if err != nil {
+			log.Printf("scanner: cannot list %s records: %v", category, err)
+			continue
+		}
+
+		for _, record := range records {
+			if !seen[record.Hash] {
+				if err := sc.store.MarkRemoved(record.Hash); err != nil {
+					log.Printf("scanner: cannot mark %s removed: %v", record.Hash, err)
+				}
+			}
+		}
+	}
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,531 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Sort selects the ordering applied to a search result set.
+type Sort int
+
+const (
+	SortDateAdded Sort = iota
+	SortRelevance
+	SortRandom
+	SortViews
+	SortFavorites
+	SortToplist
+)
+
+func (s Sort) String() string {
+	switch s {
+	case SortDateAdded:
+		return "date_added"
+	case SortRelevance:
+		return "relevance"
+	case SortRandom:
+		return "random"
+	case SortViews:
+		return "views"
+	case SortFavorites:
+		return "favorites"
+	case SortToplist:
+		return "toplist"
+	default:
+		return "date_added"
+	}
+}
+
+func parseSort(raw string) (Sort, bool) {
+	switch raw {
+	case "", "date_added":
+		return SortDateAdded, true
+	case "relevance":
+		return SortRelevance, true
+	case "random":
+		return SortRandom, true
+	case "views":
+		return SortViews, true
+	case "favorites":
+		return SortFavorites, true
+	case "toplist":
+		return SortToplist, true
+	default:
+		return SortDateAdded, false
+	}
+}
+
+// Order selects ascending or descending results for a given Sort.
+type Order int
+
+const (
+	OrderDesc Order = iota
+	OrderAsc
+)
+
+func (o Order) String() string {
+	if o == OrderAsc {
+		return "asc"
+	}
+	return "desc"
+}
+
+func parseOrder(raw string) (Order, bool) {
+	switch raw {
+	case "", "desc":
+		return OrderDesc, true
+	case "asc":
+		return OrderAsc, true
+	default:
+		return OrderDesc, false
+	}
+}
+
+// TopRange bounds a sort=toplist query to wallpapers added within a window.
+type TopRange int
+
+const (
+	TopRange1d TopRange = iota
+	TopRange3d
+	TopRange1w
+	TopRange1M
+	TopRange3M
+	TopRange6M
+	TopRange1y
+)
+
+func (t TopRange) String() string {
+	switch t {
+	case TopRange1d:
+		return "1d"
+	case TopRange3d:
+		return "3d"
+	case TopRange1w:
+		return "1w"
+	case TopRange1M:
+		return "1M"
+	case TopRange3M:
+		return "3M"
+	case TopRange6M:
+		return "6M"
+	case TopRange1y:
+		return "1y"
+	default:
+		return "1w"
+	}
+}
+
+// Duration returns the lookback window represented by the range.
+func (t TopRange) Duration() time.Duration {
+	switch t {
+	case TopRange1d:
+		return 24 * time.Hour
+	case TopRange3d:
+		return 3 * 24 * time.Hour
+	case TopRange1w:
+		return 7 * 24 * time.Hour
+	case TopRange1M:
+		return 30 * 24 * time.Hour
+	case TopRange3M:
+		return 90 * 24 * time.Hour
+	case TopRange6M:
+		return 182 * 24 * time.Hour
+	case TopRange1y:
+		return 365 * 24 * time.Hour
+	default:
+		return 7 * 24 * time.Hour
+	}
+}
+
+func parseTopRange(raw string) (TopRange, bool) {
+	switch raw {
+	case "", "1w":
+		return TopRange1w, true
+	case "1d":
+		return TopRange1d, true
+	case "3d":
+		return TopRange3d, true
+	case "1M":
+		return TopRange1M, true
+	case "3M":
+		return TopRange3M, true
+	case "6M":
+		return TopRange6M, true
+	case "1y":
+		return TopRange1y, true
+	default:
+		return TopRange1w, false
+	}
+}
+
+const (
+	defaultPerPage = 24
+	maxPerPage     = 64
+	maxPage        = 1_000_000
+)
+
+// SearchParams captures the wallhaven-style query parameters accepted by
+// /api/v1/search and /api/v1/wallpapers/:category.
+type SearchParams struct {
+	Query      string
+	Categories []string
+	Tags       []string
+	Resolution string
+	Ratio      string
+	Sort       Sort
+	Order      Order
+	TopRange   TopRange
+	Page       int
+	PerPage    int
+}
+
+// Meta describes pagination state for a search response.
+type Meta struct {
+	CurrentPage int    `json:"current_page"`
+	LastPage    int    `json:"last_page"`
+	PerPage     int    `json:"per_page"`
+	Total       int    `json:"total"`
+	Query       string `json:"query"`
+}
+
+// SearchResponse is the envelope returned by the search endpoints.
+type SearchResponse struct {
+	Success bool        `json:"success"`
+	Data    []Wallpaper `json:"data"`
+	Meta    Meta        `json:"meta,omitempty"`
+	Message string      `json:"message,omitempty"`
+}
+
+func parseSearchParams(c *gin.Context) (SearchParams, error) {
+	params := SearchParams{
+		Query:      strings.TrimSpace(c.Query("q")),
+		Resolution: strings.TrimSpace(c.Query("resolution")),
+		Ratio:      strings.TrimSpace(c.Query("ratio")),
+	}
+
+	if raw := c.Query("categories"); raw != "" {
+		for _, cat := range strings.Split(raw, ",") {
+			cat = strings.ToLower(strings.TrimSpace(cat))
+			if cat == "" {
+				continue
+			}
+			if !isValidCategory(cat) {
+				return params, fmt.Errorf("invalid category %q", cat)
+			}
+			params.Categories = append(params.Categories, cat)
+		}
+	}
+
+	if raw := c.Query("tags"); raw != "" {
+		for _, tag := range strings.Split(raw, ",") {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if tag != "" {
+				params.Tags = append(params.Tags, tag)
+			}
+		}
+	}
+
+	sortVal, ok := parseSort(c.Query("sort"))
+	if !ok {
+		return params, fmt.Errorf("invalid sort %q", c.Query("sort"))
+	}
+	params.Sort = sortVal
+
+	orderVal, ok := parseOrder(c.Query("order"))
+	if !ok {
+		return params, fmt.Errorf("invalid order %q", c.Query("order"))
+	}
+	params.Order = orderVal
+
+	topRangeVal, ok := parseTopRange(c.Query("top_range"))
+	if !ok {
+		return params, fmt.Errorf("invalid top_range %q", c.Query("top_range"))
+	}
+	params.TopRange = topRangeVal
+
+	params.Page = 1
+	if raw := c.Query("page"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxPage {
+			return params, fmt.Errorf("invalid page %q", raw)
+		}
+		params.Page = n
+	}
+
+	params.PerPage = defaultPerPage
+	if raw := c.Query("per_page"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return params, fmt.Errorf("invalid per_page %q", raw)
+		}
+		if n > maxPerPage {
+			n = maxPerPage
+		}
+		params.PerPage = n
+	}
+
+	return params, nil
+}
+
+// searchWallpapers loads every category referenced by params (or a single
+// forced category, for the /wallpapers/:category route), applies filters,
+// sorts, and paginates the result.
+func searchWallpapers(c *gin.Context, params SearchParams, forceCategory string) ([]Wallpaper, Meta, error) {
+	cats := params.Categories
+	if forceCategory != "" {
+		cats = []string{forceCategory}
+	}
+	if len(cats) == 0 {
+		cats = categories
+	}
+
+	var all []Wallpaper
+	for _, cat := range cats {
+		wallpapers, err := loadWallpapersFromFolder(c, cat)
+		if err != nil {
+			return nil, Meta{}, err
+		}
+		all = append(all, wallpapers...)
+	}
+
+	filtered := filterWallpapers(all, params)
+	sortWallpapers(filtered, params)
+
+	page, lastPage, total := paginate(filtered, params.Page, params.PerPage)
+
+	meta := Meta{
+		CurrentPage: params.Page,
+		LastPage:    lastPage,
+		PerPage:     params.PerPage,
+		Total:       total,
+		Query:       params.Query,
+	}
+
+	return page, meta, nil
+}
+
+// paginate slices filtered down to the requested page, clamping start/end
+// to the slice bounds so an out-of-range page returns an empty result
+// instead of panicking. It also reports the last page number for the
+// caller's Meta.
+func paginate(filtered []Wallpaper, page, perPage int) (pageSlice []Wallpaper, lastPage, total int) {
+	total = len(filtered)
+	lastPage = (total + perPage - 1) / perPage
+	if lastPage == 0 {
+		lastPage = 1
+	}
+
+	start := (page - 1) * perPage
+	end := start + perPage
+	if start > total {
+		start = total
+	}
+	if end > total {
+		end = total
+	}
+
+	return filtered[start:end], lastPage, total
+}
+
+func filterWallpapers(wallpapers []Wallpaper, params SearchParams) []Wallpaper {
+	filtered := make([]Wallpaper, 0, len(wallpapers))
+
+	for _, w := range wallpapers {
+		if params.Query != "" && !matchesQuery(w, params.Query) {
+			continue
+		}
+		if len(params.Tags) > 0 && !hasAllTags(w, params.Tags) {
+			continue
+		}
+		if params.Resolution != "" && !matchesResolution(w, params.Resolution) {
+			continue
+		}
+		if params.Ratio != "" && !matchesRatio(w, params.Ratio) {
+			continue
+		}
+		filtered = append(filtered, w)
+	}
+
+	return filtered
+}
+
+func matchesQuery(w Wallpaper, q string) bool {
+	q = strings.ToLower(q)
+	if strings.Contains(strings.ToLower(w.Title), q) {
+		return true
+	}
+	if strings.Contains(strings.ToLower(filepath.Base(w.ImageURL)), q) {
+		return true
+	}
+	for _, tag := range w.Tags {
+		if strings.Contains(strings.ToLower(tag), q) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAllTags(w Wallpaper, tags []string) bool {
+	have := make(map[string]bool, len(w.Tags))
+	for _, tag := range w.Tags {
+		have[strings.ToLower(tag)] = true
+	}
+	for _, tag := range tags {
+		if !have[tag] {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesResolution(w Wallpaper, resolution string) bool {
+	if strings.HasPrefix(resolution, "atleast:") {
+		minWidth, minHeight, ok := parseDimensions(strings.TrimPrefix(resolution, "atleast:"))
+		return ok && w.Width >= minWidth && w.Height >= minHeight
+	}
+	width, height, ok := parseDimensions(resolution)
+	return ok && w.Width == width && w.Height == height
+}
+
+func parseDimensions(raw string) (int, int, bool) {
+	parts := strings.SplitN(raw, "x", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	width, err1 := strconv.Atoi(parts[0])
+	height, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil || width <= 0 || height <= 0 {
+		return 0, 0, false
+	}
+	return width, height, true
+}
+
+func matchesRatio(w Wallpaper, ratio string) bool {
+	if w.Width == 0 || w.Height == 0 {
+		return false
+	}
+
+	switch ratio {
+	case "portrait":
+		return w.Height > w.Width
+	case "landscape":
+		return w.Width > w.Height
+	}
+
+	num, den, ok := parseDimensions(strings.ReplaceAll(ratio, ":", "x"))
+	if !ok {
+		return false
+	}
+
+	const tolerance = 0.01
+	target := float64(num) / float64(den)
+	actual := float64(w.Width) / float64(w.Height)
+	diff := target - actual
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= tolerance
+}
+
+func sortWallpapers(wallpapers []Wallpaper, params SearchParams) {
+	asc := params.Order == OrderAsc
+
+	switch params.Sort {
+	case SortRandom:
+		r := rand.New(rand.NewSource(time.Now().UnixNano()))
+		r.Shuffle(len(wallpapers), func(i, j int) {
+			wallpapers[i], wallpapers[j] = wallpapers[j], wallpapers[i]
+		})
+		return
+	case SortViews:
+		sort.SliceStable(wallpapers, func(i, j int) bool {
+			return lessWithOrder(wallpapers[i].Views, wallpapers[j].Views, asc)
+		})
+		return
+	case SortFavorites:
+		sort.SliceStable(wallpapers, func(i, j int) bool {
+			return lessWithOrder(wallpapers[i].Favorites, wallpapers[j].Favorites, asc)
+		})
+		return
+	case SortToplist:
+		cutoff := time.Now().Add(-params.TopRange.Duration())
+		sort.SliceStable(wallpapers, func(i, j int) bool {
+			scoreI := toplistScore(wallpapers[i], cutoff)
+			scoreJ := toplistScore(wallpapers[j], cutoff)
+			return lessWithOrder(scoreI, scoreJ, asc)
+		})
+		return
+	case SortRelevance:
+		if params.Query != "" {
+			sort.SliceStable(wallpapers, func(i, j int) bool {
+				return lessWithOrder(relevanceScore(wallpapers[i], params.Query), relevanceScore(wallpapers[j], params.Query), asc)
+			})
+			return
+		}
+		fallthrough
+	default: // SortDateAdded
+		sort.SliceStable(wallpapers, func(i, j int) bool {
+			return lessWithOrderTime(wallpapers[i].UploadedAt, wallpapers[j].UploadedAt, asc)
+		})
+	}
+}
+
+func lessWithOrder(a, b int, asc bool) bool {
+	if asc {
+		return a < b
+	}
+	return a > b
+}
+
+func lessWithOrderTime(a, b time.Time, asc bool) bool {
+	if asc {
+		return a.Before(b)
+	}
+	return a.After(b)
+}
+
+func toplistScore(w Wallpaper, cutoff time.Time) int {
+	if w.UploadedAt.Before(cutoff) {
+		return 0
+	}
+	return w.Views + w.Favorites*3
+}
+
+func relevanceScore(w Wallpaper, q string) int {
+	q = strings.ToLower(q)
+	score := 0
+	if strings.EqualFold(w.Title, q) {
+		score += 100
+	} else if strings.Contains(strings.ToLower(w.Title), q) {
+		score += 10
+	}
+	for _, tag := range w.Tags {
+		if strings.EqualFold(tag, q) {
+			score += 5
+		}
+	}
+	return score
+}
+
+func getSearchResults(c *gin.Context) {
+	params, err := parseSearchParams(c)
+	if err != nil {
+		c.JSON(400, SearchResponse{Success: false, Message: err.Error()})
+		return
+	}
+
+	data, meta, err := searchWallpapers(c, params, "")
+	if err != nil {
+		c.JSON(500, SearchResponse{Success: false, Message: fmt.Sprintf("Error loading wallpapers: %v", err)})
+		return
+	}
+
+	c.JSON(200, SearchResponse{Success: true, Data: data, Meta: meta})
+}
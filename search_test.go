@@ -0,0 +1,131 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMatchesRatio(t *testing.T) {
+	w := Wallpaper{Width: 1920, Height: 1080}
+
+	cases := []struct {
+		ratio string
+		want  bool
+	}{
+		{"landscape", true},
+		{"portrait", false},
+		{"16:9", true},
+		{"4:3", false},
+		{"bogus", false},
+	}
+
+	for _, tc := range cases {
+		if got := matchesRatio(w, tc.ratio); got != tc.want {
+			t.Errorf("matchesRatio(%+v, %q) = %v, want %v", w, tc.ratio, got, tc.want)
+		}
+	}
+
+	if matchesRatio(Wallpaper{}, "16:9") {
+		t.Error("matchesRatio with zero dimensions should always be false")
+	}
+}
+
+func TestMatchesResolution(t *testing.T) {
+	w := Wallpaper{Width: 2560, Height: 1440}
+
+	cases := []struct {
+		resolution string
+		want       bool
+	}{
+		{"2560x1440", true},
+		{"1920x1080", false},
+		{"atleast:1920x1080", true},
+		{"atleast:3840x2160", false},
+		{"not-a-resolution", false},
+	}
+
+	for _, tc := range cases {
+		if got := matchesResolution(w, tc.resolution); got != tc.want {
+			t.Errorf("matchesResolution(%+v, %q) = %v, want %v", w, tc.resolution, got, tc.want)
+		}
+	}
+}
+
+func TestSortWallpapersByViewsDescending(t *testing.T) {
+	wallpapers := []Wallpaper{
+		{ID: 1, Views: 5},
+		{ID: 2, Views: 20},
+		{ID: 3, Views: 10},
+	}
+
+	sortWallpapers(wallpapers, SearchParams{Sort: SortViews, Order: OrderDesc})
+
+	want := []int{2, 3, 1}
+	for i, w := range wallpapers {
+		if w.ID != want[i] {
+			t.Fatalf("position %d: got ID %d, want %d", i, w.ID, want[i])
+		}
+	}
+}
+
+func TestSortWallpapersByDateAddedAscending(t *testing.T) {
+	now := time.Now()
+	wallpapers := []Wallpaper{
+		{ID: 1, UploadedAt: now},
+		{ID: 2, UploadedAt: now.Add(-time.Hour)},
+		{ID: 3, UploadedAt: now.Add(time.Hour)},
+	}
+
+	sortWallpapers(wallpapers, SearchParams{Sort: SortDateAdded, Order: OrderAsc})
+
+	want := []int{2, 1, 3}
+	for i, w := range wallpapers {
+		if w.ID != want[i] {
+			t.Fatalf("position %d: got ID %d, want %d", i, w.ID, want[i])
+		}
+	}
+}
+
+func TestPaginateClampsOutOfRangePage(t *testing.T) {
+	// Regression test for the overflow fix: a page far past the last page
+	// must clamp to an empty result rather than producing a negative
+	// slice bound.
+	filtered := make([]Wallpaper, 5)
+
+	page, lastPage, total := paginate(filtered, maxPage, defaultPerPage)
+
+	if len(page) != 0 {
+		t.Fatalf("expected empty page for out-of-range page, got %d items", len(page))
+	}
+	if total != 5 {
+		t.Errorf("total = %d, want 5", total)
+	}
+	if lastPage != 1 {
+		t.Errorf("lastPage = %d, want 1", lastPage)
+	}
+}
+
+func TestPaginateSlicesRequestedPage(t *testing.T) {
+	filtered := make([]Wallpaper, 10)
+	for i := range filtered {
+		filtered[i].ID = i
+	}
+
+	page, lastPage, total := paginate(filtered, 2, 4)
+
+	if total != 10 {
+		t.Errorf("total = %d, want 10", total)
+	}
+	if lastPage != 3 {
+		t.Errorf("lastPage = %d, want 3", lastPage)
+	}
+	want := []int{4, 5, 6, 7}
+	if len(page) != len(want) {
+		t.Fatalf("page length = %d, want %d", len(page), len(want))
+	}
+	for i, w := range page {
+		if w.ID != want[i] {
+			t.Errorf("position %d: got ID %d, want %d", i, w.ID, want[i])
+		}
+	}
+}
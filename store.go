@@ -0,0 +1,270 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+var (
+	bucketWallpapers  = []byte("wallpapers")
+	bucketMeta        = []byte("meta")
+	bucketViewedBy    = []byte("viewed_by")
+	bucketFavoritedBy = []byte("favorited_by")
+)
+
+// WallpaperRecord is the persistent metadata kept for a single image file,
+// keyed by the sha256 hash of its contents so the same file always maps
+// back to the same ID, title and tags across restarts and re-scans.
+type WallpaperRecord struct {
+	ID         int       `json:"id"`
+	Hash       string    `json:"hash"`
+	Category   string    `json:"category"`
+	Filename   string    `json:"filename"`
+	Title      string    `json:"title"`
+	Tags       []string  `json:"tags"`
+	Width      int       `json:"width"`
+	Height     int       `json:"height"`
+	UploadedAt time.Time `json:"uploaded_at"`
+	Views      int       `json:"views"`
+	Favorites  int       `json:"favorites"`
+	Removed    bool      `json:"removed"`
+	Credit     string    `json:"credit,omitempty"`
+	License    string    `json:"license,omitempty"`
+	NSFW       bool      `json:"nsfw"`
+}
+
+// Store is a BoltDB-backed catalog of wallpaper metadata, separate from
+// the image files themselves so IDs, view counts and favorites survive
+// restarts and filesystem rescans.
+type Store struct {
+	db *bbolt.DB
+}
+
+// NewStore opens (creating if necessary) the BoltDB catalog at path.
+func NewStore(path string) (*Store, error) {
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("cannot open store %s: %v", path, err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, bucket := range [][]byte{bucketWallpapers, bucketMeta, bucketViewedBy, bucketFavoritedBy} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("cannot initialize store buckets: %v", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close releases the underlying database file.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// GetOrCreate returns the existing record for hash, or creates one with a
+// freshly allocated, stable ID if this is the first time the file has been
+// seen. Existing records have their width/height/filename refreshed in
+// case the file moved within its category folder.
+// override, when non-nil, is the curator-supplied manifest entry for this
+// filename; its fields take precedence over the random/generated defaults
+// and are re-applied on every scan so manifest edits stick.
+func (s *Store) GetOrCreate(category, filename, hash string, width, height int, uploadedAt time.Time, override *ManifestEntry) (WallpaperRecord, error) {
+	var record WallpaperRecord
+
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		wallpapers := tx.Bucket(bucketWallpapers)
+
+		if raw := wallpapers.Get([]byte(hash)); raw != nil {
+			if err := json.Unmarshal(raw, &record); err != nil {
+				return err
+			}
+			record.Category = category
+			record.Filename = filename
+			record.Width = width
+			record.Height = height
+			record.Removed = false
+			applyManifestOverride(&record, override)
+			return putRecord(wallpapers, &record)
+		}
+
+		id, err := nextID(tx.Bucket(bucketMeta))
+		if err != nil {
+			return err
+		}
+
+		record = WallpaperRecord{
+			ID:         id,
+			Hash:       hash,
+			Category:   category,
+			Filename:   filename,
+			Title:      generateRandomTitle(category),
+			Tags:       generateRandomTags(category),
+			Width:      width,
+			Height:     height,
+			UploadedAt: uploadedAt,
+		}
+		applyManifestOverride(&record, override)
+
+		return putRecord(wallpapers, &record)
+	})
+
+	return record, err
+}
+
+// MarkRemoved flags a previously catalogued file as no longer present on
+// disk, without discarding its view/favorite history in case it returns.
+func (s *Store) MarkRemoved(hash string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		wallpapers := tx.Bucket(bucketWallpapers)
+		raw := wallpapers.Get([]byte(hash))
+		if raw == nil {
+			return nil
+		}
+
+		var record WallpaperRecord
+		if err := json.Unmarshal(raw, &record); err != nil {
+			return err
+		}
+		record.Removed = true
+		return putRecord(wallpapers, &record)
+	})
+}
+
+// ByCategory returns every non-removed record catalogued for category.
+func (s *Store) ByCategory(category string) ([]WallpaperRecord, error) {
+	var records []WallpaperRecord
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(bucketWallpapers).ForEach(func(_, raw []byte) error {
+			var record WallpaperRecord
+			if err := json.Unmarshal(raw, &record); err != nil {
+				return err
+			}
+			if !record.Removed && record.Category == category {
+				records = append(records, record)
+			}
+			return nil
+		})
+	})
+
+	return records, err
+}
+
+// RecordView increments the view count for hash the first time clientKey
+// is seen for it, and is a no-op on subsequent calls from the same client.
+func (s *Store) RecordView(hash, clientKey string) (int, error) {
+	views := 0
+
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		record, ok, err := getRecord(tx.Bucket(bucketWallpapers), hash)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("unknown wallpaper hash %q", hash)
+		}
+
+		seenKey := []byte(hash + "|" + clientKey)
+		viewedBy := tx.Bucket(bucketViewedBy)
+		if viewedBy.Get(seenKey) == nil {
+			record.Views++
+			if err := viewedBy.Put(seenKey, []byte{1}); err != nil {
+				return err
+			}
+		}
+
+		views = record.Views
+		return putRecord(tx.Bucket(bucketWallpapers), &record)
+	})
+
+	return views, err
+}
+
+// RecordFavorite increments the favorite count for hash the first time
+// clientKey is seen for it, and is a no-op on subsequent calls.
+func (s *Store) RecordFavorite(hash, clientKey string) (int, error) {
+	favorites := 0
+
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		record, ok, err := getRecord(tx.Bucket(bucketWallpapers), hash)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("unknown wallpaper hash %q", hash)
+		}
+
+		seenKey := []byte(hash + "|" + clientKey)
+		favoritedBy := tx.Bucket(bucketFavoritedBy)
+		if favoritedBy.Get(seenKey) == nil {
+			record.Favorites++
+			if err := favoritedBy.Put(seenKey, []byte{1}); err != nil {
+				return err
+			}
+		}
+
+		favorites = record.Favorites
+		return putRecord(tx.Bucket(bucketWallpapers), &record)
+	})
+
+	return favorites, err
+}
+
+// ByID returns the record with the given stable ID, if one exists.
+func (s *Store) ByID(id int) (WallpaperRecord, bool, error) {
+	var found WallpaperRecord
+	var ok bool
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(bucketWallpapers).ForEach(func(_, raw []byte) error {
+			var record WallpaperRecord
+			if err := json.Unmarshal(raw, &record); err != nil {
+				return err
+			}
+			if record.ID == id {
+				found, ok = record, true
+			}
+			return nil
+		})
+	})
+
+	return found, ok, err
+}
+
+func putRecord(bucket *bbolt.Bucket, record *WallpaperRecord) error {
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return bucket.Put([]byte(record.Hash), raw)
+}
+
+func getRecord(bucket *bbolt.Bucket, hash string) (WallpaperRecord, bool, error) {
+	var record WallpaperRecord
+	raw := bucket.Get([]byte(hash))
+	if raw == nil {
+		return record, false, nil
+	}
+	if err := json.Unmarshal(raw, &record); err != nil {
+		return record, false, err
+	}
+	return record, true, nil
+}
+
+func nextID(meta *bbolt.Bucket) (int, error) {
+	id, err := meta.NextSequence()
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}